@@ -1,54 +1,274 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
+	"time"
 
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/pkg/statebased"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
 
+// AdminMSPID is the org MSP trusted as a platform administrator regardless
+// of caller attributes.
+const AdminMSPID = "AurumMSP"
+
 // AurumChaincode provides functions for managing trades and settlements
 type AurumChaincode struct {
 	contractapi.Contract
 }
 
-// TradeRecord represents a trade on the blockchain
+// TradeRecord is the public envelope of a trade, written to the world
+// state where every peer can see it. The confidential quantity/price
+// detail lives in the aurumTradeDetails private data collection instead;
+// DetailsHash lets any peer verify that detail against this envelope
+// without being able to see it.
 type TradeRecord struct {
-	TradeID    string `json:"trade_id"`
-	UserID     string `json:"user_id"`
-	Symbol     string `json:"symbol"`
-	Side       string `json:"side"`
-	Quantity   int    `json:"quantity"`
-	Price      string `json:"price"`
-	Timestamp  string `json:"timestamp"`
-	Strategy   string `json:"strategy"`
-	Status     string `json:"status"`
+	TradeID     string `json:"trade_id"`
+	UserID      string `json:"user_id"`
+	Symbol      string `json:"symbol"`
+	Side        string `json:"side"`
+	Timestamp   string `json:"timestamp"`
+	Strategy    string `json:"strategy"`
+	Status      string `json:"status"`
+	Owner       Owner  `json:"owner"`
+	DetailsHash string `json:"details_hash"`
+}
+
+// TradeDetails is the confidential portion of a trade - quantity, price,
+// and any client-supplied order metadata - written only to the
+// aurumTradeDetails private data collection, never to the world state.
+type TradeDetails struct {
+	Quantity      int    `json:"quantity"`
+	Price         string `json:"price"`
+	ClientOrderID string `json:"client_order_id,omitempty"`
+	Notes         string `json:"notes,omitempty"`
+}
+
+// TradeInput is the wire shape accepted by RecordTrade and
+// RecordTradeWithTransient: the public TradeRecord fields plus the
+// confidential TradeDetails fields, combined because the caller submits
+// them together before the chaincode splits them across world state and
+// the private collection.
+type TradeInput struct {
+	TradeID       string `json:"trade_id"`
+	UserID        string `json:"user_id"`
+	Symbol        string `json:"symbol"`
+	Side          string `json:"side"`
+	Timestamp     string `json:"timestamp"`
+	Strategy      string `json:"strategy"`
+	Status        string `json:"status"`
+	Quantity      int    `json:"quantity"`
+	Price         string `json:"price"`
+	ClientOrderID string `json:"client_order_id,omitempty"`
+	Notes         string `json:"notes,omitempty"`
+}
+
+// privateCollectionTradeDetails is the private data collection holding
+// TradeDetails, scoped per collections_config.json to the trader's org,
+// a compliance org, and AurumMSP (the admin org, which must also be a
+// collection member since MatchAndSettle reads both sides' details).
+const privateCollectionTradeDetails = "aurumTradeDetails"
+
+// Owner identifies the MSP client identity that submitted a trade, stamped
+// by RecordTrade rather than accepted from the caller. It is the unit of
+// comparison used to enforce that only a trade's owner (or an admin) may
+// read or update it.
+type Owner struct {
+	MSPID      string `json:"msp_id"`
+	CommonName string `json:"common_name"`
+}
+
+// Equal reports whether o and other identify the same client identity.
+func (o Owner) Equal(other Owner) bool {
+	return o.MSPID == other.MSPID && o.CommonName == other.CommonName
+}
+
+// AccessControlList is the ACL:<userID> record binding an app-level user ID
+// to the MSP identity that first recorded a trade under it, plus any
+// identities that user has delegated read access to (e.g. a compliance
+// auditor).
+type AccessControlList struct {
+	UserID           string  `json:"user_id"`
+	Owner            Owner   `json:"owner"`
+	DelegatedReaders []Owner `json:"delegated_readers"`
 }
 
 // SettlementRecord represents a settlement on the blockchain
 type SettlementRecord struct {
-	SettlementID string `json:"settlement_id"`
-	TradeID      string `json:"trade_id"`
-	UserID       string `json:"user_id"`
-	Profit       string `json:"profit"`
-	Status       string `json:"status"`
-	Timestamp    string `json:"timestamp"`
+	SettlementID    string `json:"settlement_id"`
+	TradeID         string `json:"trade_id"`
+	UserID          string `json:"user_id"`
+	Profit          string `json:"profit"`
+	Status          string `json:"status"`
+	Timestamp       string `json:"timestamp"`
+	BuyTradeID      string `json:"buy_trade_id,omitempty"`
+	SellTradeID     string `json:"sell_trade_id,omitempty"`
+	MatchedQuantity int    `json:"matched_quantity,omitempty"`
+	MatchedPrice    string `json:"matched_price,omitempty"`
+}
+
+// Trade sides.
+const (
+	TradeSideBuy  = "BUY"
+	TradeSideSell = "SELL"
+)
+
+// Trade lifecycle statuses.
+const (
+	TradeStatusOpen      = "OPEN"
+	TradeStatusSettled   = "SETTLED"
+	TradeStatusCancelled = "CANCELLED"
+)
+
+// Settlement lifecycle statuses.
+const (
+	SettlementStatusPending   = "PENDING"
+	SettlementStatusFinalized = "FINALIZED"
+)
+
+// allowedTradeTransitions enumerates the trade statuses reachable from a
+// given status. Any transition not listed here is rejected by
+// UpdateTradeStatus so trades cannot be pushed into an inconsistent state.
+var allowedTradeTransitions = map[string][]string{
+	TradeStatusOpen:      {TradeStatusSettled, TradeStatusCancelled},
+	TradeStatusSettled:   {},
+	TradeStatusCancelled: {},
+}
+
+// allowedSettlementTransitions enumerates the settlement statuses reachable
+// from a given status.
+var allowedSettlementTransitions = map[string][]string{
+	SettlementStatusPending:   {SettlementStatusFinalized},
+	SettlementStatusFinalized: {},
+}
+
+// Chaincode event names. Off-chain services (order manager, ledger
+// reconciler, notifier) subscribe to the peer's block event stream and
+// filter on these names instead of polling query endpoints such as
+// QueryTradeByID on a timer.
+const (
+	EventTradeRecorded       = "trade.recorded"
+	EventTradeStatusChanged  = "trade.status_changed"
+	EventSettlementRecorded  = "settlement.recorded"
+	EventSettlementFinalized = "settlement.finalized"
+	EventSettlementMatched   = "settlement.matched"
+)
+
+// TradeEvent is the JSON envelope published on trade.recorded and
+// trade.status_changed. PreviousStatus is empty on trade.recorded.
+type TradeEvent struct {
+	TradeID        string `json:"trade_id"`
+	UserID         string `json:"user_id"`
+	PreviousStatus string `json:"previous_status"`
+	NewStatus      string `json:"new_status"`
+	Timestamp      string `json:"timestamp"`
+}
+
+// SettlementEvent is the JSON envelope published on settlement.recorded and
+// settlement.finalized. PreviousStatus is empty on settlement.recorded.
+type SettlementEvent struct {
+	SettlementID   string `json:"settlement_id"`
+	UserID         string `json:"user_id"`
+	PreviousStatus string `json:"previous_status"`
+	NewStatus      string `json:"new_status"`
+	Timestamp      string `json:"timestamp"`
 }
 
-// RecordTrade records a trade on the blockchain
+// RecordTrade records a trade on the blockchain. The quantity and price
+// carried in tradeJSON are written to the aurumTradeDetails private data
+// collection rather than the world state; callers that need those fields
+// to never enter the broadcast transaction proposal should use
+// RecordTradeWithTransient instead.
 func (s *AurumChaincode) RecordTrade(ctx contractapi.TransactionContextInterface, tradeJSON string) error {
-	var trade TradeRecord
-	err := json.Unmarshal([]byte(tradeJSON), &trade)
+	var input TradeInput
+	if err := json.Unmarshal([]byte(tradeJSON), &input); err != nil {
+		return fmt.Errorf("failed to unmarshal trade: %v", err)
+	}
+	return recordTrade(ctx, input)
+}
+
+// RecordTradeWithTransient records a trade the same way RecordTrade does,
+// but reads the full trade - including the confidential quantity and
+// price - from the transaction's transient map under the "trade" key
+// instead of from an argument, so that data never enters the transaction
+// proposal that gets broadcast to the ordering service and other peers.
+func (s *AurumChaincode) RecordTradeWithTransient(ctx contractapi.TransactionContextInterface) error {
+	transientMap, err := ctx.GetStub().GetTransient()
 	if err != nil {
+		return fmt.Errorf("failed to get transient map: %v", err)
+	}
+
+	tradeBytes, ok := transientMap["trade"]
+	if !ok {
+		return fmt.Errorf("transient map must contain a 'trade' key")
+	}
+
+	var input TradeInput
+	if err := json.Unmarshal(tradeBytes, &input); err != nil {
 		return fmt.Errorf("failed to unmarshal trade: %v", err)
 	}
+	return recordTrade(ctx, input)
+}
+
+// recordTrade splits input into a public TradeRecord and a private
+// TradeDetails, stamps the caller's identity as owner, and writes both.
+func recordTrade(ctx contractapi.TransactionContextInterface, input TradeInput) error {
+	trade := TradeRecord{
+		TradeID:   input.TradeID,
+		UserID:    input.UserID,
+		Symbol:    input.Symbol,
+		Side:      input.Side,
+		Timestamp: input.Timestamp,
+		Strategy:  input.Strategy,
+		Status:    input.Status,
+	}
+	if trade.Status == "" {
+		trade.Status = TradeStatusOpen
+	}
+
+	owner, err := currentOwner(ctx)
+	if err != nil {
+		return err
+	}
+	trade.Owner = owner
+
+	if err := bindUserOwnership(ctx, trade.UserID, owner); err != nil {
+		return err
+	}
+
+	if err := guardTradeOverwrite(ctx, trade.TradeID, owner); err != nil {
+		return err
+	}
+
+	details := TradeDetails{
+		Quantity:      input.Quantity,
+		Price:         input.Price,
+		ClientOrderID: input.ClientOrderID,
+		Notes:         input.Notes,
+	}
+	detailsHash, err := canonicalHash(details)
+	if err != nil {
+		return err
+	}
+	trade.DetailsHash = detailsHash
 
-	// Store trade
 	tradeKey := fmt.Sprintf("TRADE:%s", trade.TradeID)
-	tradeBytes, _ := json.Marshal(trade)
-	err = ctx.GetStub().PutState(tradeKey, tradeBytes)
+
+	detailsBytes, err := json.Marshal(details)
 	if err != nil {
+		return fmt.Errorf("failed to marshal trade details: %v", err)
+	}
+	if err := ctx.GetStub().PutPrivateData(privateCollectionTradeDetails, tradeKey, detailsBytes); err != nil {
+		return fmt.Errorf("failed to put private trade details: %v", err)
+	}
+
+	tradeBytes, _ := json.Marshal(trade)
+	if err := ctx.GetStub().PutState(tradeKey, tradeBytes); err != nil {
 		return fmt.Errorf("failed to put trade: %v", err)
 	}
 
@@ -57,11 +277,267 @@ func (s *AurumChaincode) RecordTrade(ctx contractapi.TransactionContextInterface
 	if err != nil {
 		return fmt.Errorf("failed to create composite key: %v", err)
 	}
-	err = ctx.GetStub().PutState(userTradeKey, []byte(trade.TradeID))
-	if err != nil {
+	if err := ctx.GetStub().PutState(userTradeKey, []byte(trade.TradeID)); err != nil {
 		return fmt.Errorf("failed to put user trade: %v", err)
 	}
 
+	return emitTradeEvent(ctx, EventTradeRecorded, TradeEvent{
+		TradeID:   trade.TradeID,
+		UserID:    trade.UserID,
+		NewStatus: trade.Status,
+		Timestamp: txTimestamp(ctx),
+	})
+}
+
+// guardTradeOverwrite rejects writes to an existing TradeID whose recorded
+// Owner does not match the caller, so one trader's RecordTrade call cannot
+// clobber another trader's trade (public record or private details) by
+// reusing its TradeID. Admins may still overwrite, consistent with
+// bindUserOwnership.
+func guardTradeOverwrite(ctx contractapi.TransactionContextInterface, tradeID string, owner Owner) error {
+	tradeKey := fmt.Sprintf("TRADE:%s", tradeID)
+	existingBytes, err := ctx.GetStub().GetState(tradeKey)
+	if err != nil {
+		return fmt.Errorf("failed to check existing trade: %v", err)
+	}
+	if existingBytes == nil {
+		return nil
+	}
+	var existing TradeRecord
+	if err := json.Unmarshal(existingBytes, &existing); err != nil {
+		return fmt.Errorf("failed to unmarshal existing trade: %v", err)
+	}
+	if existing.Owner.Equal(owner) {
+		return nil
+	}
+	isAdminCaller, err := isAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	if !isAdminCaller {
+		return fmt.Errorf("identity is not authorized to overwrite trade %s", tradeID)
+	}
+	return nil
+}
+
+// QueryTradePrivate returns the confidential TradeDetails for a trade,
+// gated by the same ownership/ACL rules as QueryTradeByID.
+func (s *AurumChaincode) QueryTradePrivate(ctx contractapi.TransactionContextInterface, tradeID string) (*TradeDetails, error) {
+	trade, err := getTrade(ctx, tradeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeUserAccess(ctx, trade.UserID); err != nil {
+		return nil, err
+	}
+	return getTradeDetails(ctx, tradeID)
+}
+
+// getTradeDetails loads and unmarshals the private TradeDetails for a
+// trade from the aurumTradeDetails collection.
+func getTradeDetails(ctx contractapi.TransactionContextInterface, tradeID string) (*TradeDetails, error) {
+	tradeKey := fmt.Sprintf("TRADE:%s", tradeID)
+	detailsBytes, err := ctx.GetStub().GetPrivateData(privateCollectionTradeDetails, tradeKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get private trade details: %v", err)
+	}
+	if detailsBytes == nil {
+		return nil, fmt.Errorf("private trade details for %s not found", tradeID)
+	}
+
+	var details TradeDetails
+	if err := json.Unmarshal(detailsBytes, &details); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal trade details: %v", err)
+	}
+	return &details, nil
+}
+
+// UpdateTradeStatus transitions a trade to newStatus, rejecting any
+// transition not present in allowedTradeTransitions, and publishes a
+// trade.status_changed event carrying the previous and next status.
+func (s *AurumChaincode) UpdateTradeStatus(ctx contractapi.TransactionContextInterface, tradeID string, newStatus string) error {
+	_, err := transitionTradeStatus(ctx, tradeID, newStatus, true)
+	return err
+}
+
+// CancelTrade cancels an OPEN trade. Trades that have already transitioned
+// to SETTLED are rejected by the same transition table UpdateTradeStatus
+// uses, so a trade that is part of an in-flight or completed match can
+// never be cancelled out from under it.
+func (s *AurumChaincode) CancelTrade(ctx contractapi.TransactionContextInterface, tradeID string) error {
+	_, err := transitionTradeStatus(ctx, tradeID, TradeStatusCancelled, true)
+	return err
+}
+
+// MatchAndSettle matches an OPEN buy trade against an OPEN sell trade in
+// the same symbol and quantity, writes the resulting SettlementRecord, and
+// transitions both trades to SETTLED, all within a single transaction so
+// endorsement commits the whole match or none of it. Only an admin
+// identity may broker a match, since matching two counterparties' trades
+// is not an act either counterparty can unilaterally authorize.
+func (s *AurumChaincode) MatchAndSettle(ctx contractapi.TransactionContextInterface, buyTradeID string, sellTradeID string, settlementJSON string) error {
+	isAdminCaller, err := isAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	if !isAdminCaller {
+		return fmt.Errorf("identity is not authorized to match and settle trades")
+	}
+
+	buyTrade, err := getTrade(ctx, buyTradeID)
+	if err != nil {
+		return err
+	}
+	sellTrade, err := getTrade(ctx, sellTradeID)
+	if err != nil {
+		return err
+	}
+
+	if buyTrade.Symbol != sellTrade.Symbol {
+		return fmt.Errorf("trades %s and %s are not in the same symbol", buyTradeID, sellTradeID)
+	}
+	if buyTrade.Side != TradeSideBuy || sellTrade.Side != TradeSideSell {
+		return fmt.Errorf("trades %s and %s are not opposite sides of a BUY/SELL pair", buyTradeID, sellTradeID)
+	}
+	if buyTrade.Status != TradeStatusOpen || sellTrade.Status != TradeStatusOpen {
+		return fmt.Errorf("trades %s and %s must both be OPEN to match", buyTradeID, sellTradeID)
+	}
+
+	buyDetails, err := getTradeDetails(ctx, buyTradeID)
+	if err != nil {
+		return err
+	}
+	sellDetails, err := getTradeDetails(ctx, sellTradeID)
+	if err != nil {
+		return err
+	}
+	if buyDetails.Quantity != sellDetails.Quantity {
+		return fmt.Errorf("trades %s and %s do not have matching quantities", buyTradeID, sellTradeID)
+	}
+
+	var settlement SettlementRecord
+	if err := json.Unmarshal([]byte(settlementJSON), &settlement); err != nil {
+		return fmt.Errorf("failed to unmarshal settlement: %v", err)
+	}
+	settlement.BuyTradeID = buyTradeID
+	settlement.SellTradeID = sellTradeID
+	settlement.MatchedQuantity = buyDetails.Quantity
+	settlement.MatchedPrice = buyDetails.Price
+	settlement.Status = SettlementStatusFinalized
+
+	settlementKey := fmt.Sprintf("SETTLEMENT:%s", settlement.SettlementID)
+	settlementBytes, err := json.Marshal(settlement)
+	if err != nil {
+		return fmt.Errorf("failed to marshal settlement: %v", err)
+	}
+	if err := ctx.GetStub().PutState(settlementKey, settlementBytes); err != nil {
+		return fmt.Errorf("failed to put settlement: %v", err)
+	}
+	if err := requireOrgEndorsement(ctx, settlementKey, buyTrade.Owner.MSPID, sellTrade.Owner.MSPID); err != nil {
+		return err
+	}
+
+	if _, err := transitionTradeStatus(ctx, buyTradeID, TradeStatusSettled, false); err != nil {
+		return err
+	}
+	if _, err := transitionTradeStatus(ctx, sellTradeID, TradeStatusSettled, false); err != nil {
+		return err
+	}
+
+	return emitSettlementEvent(ctx, EventSettlementMatched, SettlementEvent{
+		SettlementID: settlement.SettlementID,
+		UserID:       settlement.UserID,
+		NewStatus:    settlement.Status,
+		Timestamp:    txTimestamp(ctx),
+	})
+}
+
+// transitionTradeStatus loads a trade, optionally authorizes the caller
+// against its owner, validates the status transition, persists it and
+// emits a trade.status_changed event. checkAuth is false for transitions
+// driven by an already-authorized operation such as MatchAndSettle.
+func transitionTradeStatus(ctx contractapi.TransactionContextInterface, tradeID string, newStatus string, checkAuth bool) (*TradeRecord, error) {
+	tradeKey := fmt.Sprintf("TRADE:%s", tradeID)
+	tradeBytes, err := ctx.GetStub().GetState(tradeKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trade: %v", err)
+	}
+	if tradeBytes == nil {
+		return nil, fmt.Errorf("trade %s does not exist", tradeID)
+	}
+
+	var trade TradeRecord
+	if err := json.Unmarshal(tradeBytes, &trade); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal trade: %v", err)
+	}
+
+	if checkAuth {
+		if err := authorizeUserWrite(ctx, trade.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	if !isTransitionAllowed(allowedTradeTransitions, trade.Status, newStatus) {
+		return nil, fmt.Errorf("invalid trade status transition from %q to %q", trade.Status, newStatus)
+	}
+
+	previousStatus := trade.Status
+	trade.Status = newStatus
+
+	tradeBytes, _ = json.Marshal(trade)
+	if err := ctx.GetStub().PutState(tradeKey, tradeBytes); err != nil {
+		return nil, fmt.Errorf("failed to put trade: %v", err)
+	}
+
+	if err := emitTradeEvent(ctx, EventTradeStatusChanged, TradeEvent{
+		TradeID:        trade.TradeID,
+		UserID:         trade.UserID,
+		PreviousStatus: previousStatus,
+		NewStatus:      newStatus,
+		Timestamp:      txTimestamp(ctx),
+	}); err != nil {
+		return nil, err
+	}
+
+	return &trade, nil
+}
+
+// getTrade loads and unmarshals a TradeRecord by ID.
+func getTrade(ctx contractapi.TransactionContextInterface, tradeID string) (*TradeRecord, error) {
+	tradeKey := fmt.Sprintf("TRADE:%s", tradeID)
+	tradeBytes, err := ctx.GetStub().GetState(tradeKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trade: %v", err)
+	}
+	if tradeBytes == nil {
+		return nil, fmt.Errorf("trade %s does not exist", tradeID)
+	}
+
+	var trade TradeRecord
+	if err := json.Unmarshal(tradeBytes, &trade); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal trade: %v", err)
+	}
+	return &trade, nil
+}
+
+// requireOrgEndorsement sets a state-based endorsement policy on key
+// requiring a signature from each of the given member orgs, so no single
+// org's peer can unilaterally commit a later update to that key.
+func requireOrgEndorsement(ctx contractapi.TransactionContextInterface, key string, mspIDs ...string) error {
+	ep, err := statebased.NewStateEP(nil)
+	if err != nil {
+		return fmt.Errorf("failed to build endorsement policy: %v", err)
+	}
+	if err := ep.AddOrgs(statebased.RoleTypeMember, mspIDs...); err != nil {
+		return fmt.Errorf("failed to add orgs to endorsement policy: %v", err)
+	}
+	epBytes, err := ep.Policy()
+	if err != nil {
+		return fmt.Errorf("failed to serialize endorsement policy: %v", err)
+	}
+	if err := ctx.GetStub().SetStateValidationParameter(key, epBytes); err != nil {
+		return fmt.Errorf("failed to set endorsement policy for %s: %v", key, err)
+	}
 	return nil
 }
 
@@ -73,6 +549,18 @@ func (s *AurumChaincode) RecordSettlement(ctx contractapi.TransactionContextInte
 		return fmt.Errorf("failed to unmarshal settlement: %v", err)
 	}
 
+	if settlement.Status == "" {
+		settlement.Status = SettlementStatusPending
+	}
+
+	owner, err := currentOwner(ctx)
+	if err != nil {
+		return err
+	}
+	if err := bindUserOwnership(ctx, settlement.UserID, owner); err != nil {
+		return err
+	}
+
 	// Store settlement
 	settlementKey := fmt.Sprintf("SETTLEMENT:%s", settlement.SettlementID)
 	settlementBytes, _ := json.Marshal(settlement)
@@ -81,7 +569,203 @@ func (s *AurumChaincode) RecordSettlement(ctx contractapi.TransactionContextInte
 		return fmt.Errorf("failed to put settlement: %v", err)
 	}
 
-	return nil
+	return emitSettlementEvent(ctx, EventSettlementRecorded, SettlementEvent{
+		SettlementID: settlement.SettlementID,
+		UserID:       settlement.UserID,
+		NewStatus:    settlement.Status,
+		Timestamp:    txTimestamp(ctx),
+	})
+}
+
+// FinalizeSettlement transitions a settlement from PENDING to FINALIZED,
+// rejecting the call if the settlement is not currently pending, and
+// publishes a settlement.finalized event.
+func (s *AurumChaincode) FinalizeSettlement(ctx contractapi.TransactionContextInterface, settlementID string) error {
+	settlementKey := fmt.Sprintf("SETTLEMENT:%s", settlementID)
+	settlementBytes, err := ctx.GetStub().GetState(settlementKey)
+	if err != nil {
+		return fmt.Errorf("failed to get settlement: %v", err)
+	}
+	if settlementBytes == nil {
+		return fmt.Errorf("settlement %s does not exist", settlementID)
+	}
+
+	var settlement SettlementRecord
+	if err := json.Unmarshal(settlementBytes, &settlement); err != nil {
+		return fmt.Errorf("failed to unmarshal settlement: %v", err)
+	}
+
+	if err := authorizeUserWrite(ctx, settlement.UserID); err != nil {
+		return err
+	}
+
+	if !isTransitionAllowed(allowedSettlementTransitions, settlement.Status, SettlementStatusFinalized) {
+		return fmt.Errorf("invalid settlement status transition from %q to %q", settlement.Status, SettlementStatusFinalized)
+	}
+
+	previousStatus := settlement.Status
+	settlement.Status = SettlementStatusFinalized
+
+	settlementBytes, _ = json.Marshal(settlement)
+	if err := ctx.GetStub().PutState(settlementKey, settlementBytes); err != nil {
+		return fmt.Errorf("failed to put settlement: %v", err)
+	}
+
+	return emitSettlementEvent(ctx, EventSettlementFinalized, SettlementEvent{
+		SettlementID:   settlement.SettlementID,
+		UserID:         settlement.UserID,
+		PreviousStatus: previousStatus,
+		NewStatus:      SettlementStatusFinalized,
+		Timestamp:      txTimestamp(ctx),
+	})
+}
+
+// PaginatedTradeResult is the envelope returned by the CouchDB-backed rich
+// trade queries. Bookmark is opaque and should be passed back unmodified to
+// fetch the next page.
+type PaginatedTradeResult struct {
+	Results        []TradeRecord `json:"results"`
+	Bookmark       string        `json:"bookmark"`
+	FetchedRecords int32         `json:"fetchedRecords"`
+}
+
+// PaginatedSettlementResult is the envelope returned by the CouchDB-backed
+// rich settlement queries.
+type PaginatedSettlementResult struct {
+	Results        []SettlementRecord `json:"results"`
+	Bookmark       string             `json:"bookmark"`
+	FetchedRecords int32              `json:"fetchedRecords"`
+}
+
+// QueryTradesRich runs an arbitrary CouchDB selector against TRADE records
+// with pagination, so a dashboard can page through large result sets
+// instead of loading everything into memory. selectorJSON is a full Mango
+// query document, e.g. {"selector":{"symbol":"AAPL"}}. Since the selector
+// can span every user's trades, this is restricted to admin callers; a
+// trader-scoped equivalent is QueryOpenTradesByUser.
+func (s *AurumChaincode) QueryTradesRich(ctx contractapi.TransactionContextInterface, selectorJSON string, pageSize int32, bookmark string) (*PaginatedTradeResult, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	return runTradeQuery(ctx, selectorJSON, pageSize, bookmark)
+}
+
+// runTradeQuery executes selectorJSON against TRADE records without any
+// authorization check; callers are responsible for authorizing the caller
+// for the scope of the selector before invoking this.
+func runTradeQuery(ctx contractapi.TransactionContextInterface, selectorJSON string, pageSize int32, bookmark string) (*PaginatedTradeResult, error) {
+	iterator, metadata, err := ctx.GetStub().GetQueryResultWithPagination(selectorJSON, pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run rich trade query: %v", err)
+	}
+	defer iterator.Close()
+
+	var trades []TradeRecord
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate: %v", err)
+		}
+
+		var trade TradeRecord
+		if err := json.Unmarshal(response.Value, &trade); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal trade: %v", err)
+		}
+		trades = append(trades, trade)
+	}
+
+	return &PaginatedTradeResult{
+		Results:        trades,
+		Bookmark:       metadata.GetBookmark(),
+		FetchedRecords: metadata.GetFetchedRecordsCount(),
+	}, nil
+}
+
+// QuerySettlementsRich runs an arbitrary CouchDB selector against
+// SETTLEMENT records with pagination. Restricted to admin callers for the
+// same reason as QueryTradesRich.
+func (s *AurumChaincode) QuerySettlementsRich(ctx contractapi.TransactionContextInterface, selectorJSON string, pageSize int32, bookmark string) (*PaginatedSettlementResult, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	iterator, metadata, err := ctx.GetStub().GetQueryResultWithPagination(selectorJSON, pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run rich settlement query: %v", err)
+	}
+	defer iterator.Close()
+
+	var settlements []SettlementRecord
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate: %v", err)
+		}
+
+		var settlement SettlementRecord
+		if err := json.Unmarshal(response.Value, &settlement); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal settlement: %v", err)
+		}
+		settlements = append(settlements, settlement)
+	}
+
+	return &PaginatedSettlementResult{
+		Results:        settlements,
+		Bookmark:       metadata.GetBookmark(),
+		FetchedRecords: metadata.GetFetchedRecordsCount(),
+	}, nil
+}
+
+// QueryTradesBySymbol returns, paginated, all trades for the given symbol.
+// Backed by the (symbol, status) CouchDB index. This spans every trader's
+// trades for the symbol, so it is restricted to admin callers.
+func (s *AurumChaincode) QueryTradesBySymbol(ctx contractapi.TransactionContextInterface, symbol string, pageSize int32, bookmark string) (*PaginatedTradeResult, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	selector, err := tradeSelector(map[string]interface{}{"symbol": symbol})
+	if err != nil {
+		return nil, err
+	}
+	return runTradeQuery(ctx, selector, pageSize, bookmark)
+}
+
+// QueryTradesByStrategy returns, paginated, all trades for the given
+// strategy. Backed by the (strategy, timestamp) CouchDB index. This spans
+// every trader running the strategy, so it is restricted to admin callers.
+func (s *AurumChaincode) QueryTradesByStrategy(ctx contractapi.TransactionContextInterface, strategy string, pageSize int32, bookmark string) (*PaginatedTradeResult, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	selector, err := tradeSelector(map[string]interface{}{"strategy": strategy})
+	if err != nil {
+		return nil, err
+	}
+	return runTradeQuery(ctx, selector, pageSize, bookmark)
+}
+
+// QueryOpenTradesByUser returns, paginated, the OPEN trades belonging to
+// userID. Backed by the (user_id, status) CouchDB index. Scoped to the
+// caller's own ownership/delegation, same as QueryTradeByID.
+func (s *AurumChaincode) QueryOpenTradesByUser(ctx contractapi.TransactionContextInterface, userID string, pageSize int32, bookmark string) (*PaginatedTradeResult, error) {
+	if err := authorizeUserAccess(ctx, userID); err != nil {
+		return nil, err
+	}
+	selector, err := tradeSelector(map[string]interface{}{"user_id": userID, "status": TradeStatusOpen})
+	if err != nil {
+		return nil, err
+	}
+	return runTradeQuery(ctx, selector, pageSize, bookmark)
+}
+
+// tradeSelector marshals a field/value map into a Mango selector document
+// of the form {"selector": fields}.
+func tradeSelector(fields map[string]interface{}) (string, error) {
+	selectorBytes, err := json.Marshal(map[string]interface{}{"selector": fields})
+	if err != nil {
+		return "", fmt.Errorf("failed to build selector: %v", err)
+	}
+	return string(selectorBytes), nil
 }
 
 // QueryTradeByID queries a trade by ID
@@ -94,11 +778,24 @@ func (s *AurumChaincode) QueryTradeByID(ctx contractapi.TransactionContextInterf
 	if tradeBytes == nil {
 		return "", fmt.Errorf("trade %s does not exist", tradeID)
 	}
+
+	var trade TradeRecord
+	if err := json.Unmarshal(tradeBytes, &trade); err != nil {
+		return "", fmt.Errorf("failed to unmarshal trade: %v", err)
+	}
+	if err := authorizeUserAccess(ctx, trade.UserID); err != nil {
+		return "", err
+	}
+
 	return string(tradeBytes), nil
 }
 
 // QueryTradesByUser queries all trades for a user
 func (s *AurumChaincode) QueryTradesByUser(ctx contractapi.TransactionContextInterface, userID string) (string, error) {
+	if err := authorizeUserAccess(ctx, userID); err != nil {
+		return "", err
+	}
+
 	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey("USER_TRADE", []string{userID})
 	if err != nil {
 		return "", fmt.Errorf("failed to get user trades: %v", err)
@@ -128,6 +825,403 @@ func (s *AurumChaincode) QueryTradesByUser(ctx contractapi.TransactionContextInt
 	return string(tradesJSON), nil
 }
 
+// HistoryEntry is one modification of a key as recorded by
+// GetHistoryForKey, in blockchain order (oldest first).
+type HistoryEntry struct {
+	TxID      string          `json:"tx_id"`
+	Timestamp string          `json:"timestamp"`
+	IsDelete  bool            `json:"is_delete"`
+	Value     json.RawMessage `json:"value,omitempty"`
+}
+
+// GetTradeHistory returns the ordered list of modifications made to a
+// trade, letting an external reconciliation system audit how a record
+// reached its current state rather than trusting only the latest value.
+func (s *AurumChaincode) GetTradeHistory(ctx contractapi.TransactionContextInterface, tradeID string) ([]HistoryEntry, error) {
+	trade, err := getTrade(ctx, tradeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeUserAccess(ctx, trade.UserID); err != nil {
+		return nil, err
+	}
+	tradeKey := fmt.Sprintf("TRADE:%s", tradeID)
+	return keyHistory(ctx, tradeKey)
+}
+
+// GetSettlementHistory returns the ordered list of modifications made to a
+// settlement.
+func (s *AurumChaincode) GetSettlementHistory(ctx contractapi.TransactionContextInterface, settlementID string) ([]HistoryEntry, error) {
+	settlementKey := fmt.Sprintf("SETTLEMENT:%s", settlementID)
+	settlementBytes, err := ctx.GetStub().GetState(settlementKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settlement: %v", err)
+	}
+	if settlementBytes == nil {
+		return nil, fmt.Errorf("settlement %s does not exist", settlementID)
+	}
+	var settlement SettlementRecord
+	if err := json.Unmarshal(settlementBytes, &settlement); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal settlement: %v", err)
+	}
+	if err := authorizeUserAccess(ctx, settlement.UserID); err != nil {
+		return nil, err
+	}
+	return keyHistory(ctx, settlementKey)
+}
+
+// keyHistory drains GetHistoryForKey for key into a slice of HistoryEntry.
+func keyHistory(ctx contractapi.TransactionContextInterface, key string) ([]HistoryEntry, error) {
+	iterator, err := ctx.GetStub().GetHistoryForKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history for %s: %v", key, err)
+	}
+	defer iterator.Close()
+
+	var history []HistoryEntry
+	for iterator.HasNext() {
+		modification, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate history: %v", err)
+		}
+
+		entry := HistoryEntry{
+			TxID:     modification.GetTxId(),
+			IsDelete: modification.GetIsDelete(),
+		}
+		if ts := modification.GetTimestamp(); ts != nil {
+			entry.Timestamp = time.Unix(ts.Seconds, int64(ts.Nanos)).UTC().Format(time.RFC3339)
+		}
+		if !modification.GetIsDelete() {
+			entry.Value = modification.GetValue()
+		}
+		history = append(history, entry)
+	}
+
+	return history, nil
+}
+
+// VerifyTradeIntegrity recomputes the canonical sha256 hash of the current
+// on-chain TradeRecord and compares it against expectedHash, so an
+// off-chain book can prove its copy of a trade matches ledger state
+// without having to diff the full record.
+func (s *AurumChaincode) VerifyTradeIntegrity(ctx contractapi.TransactionContextInterface, tradeID string, expectedHash string) (bool, error) {
+	tradeKey := fmt.Sprintf("TRADE:%s", tradeID)
+	tradeBytes, err := ctx.GetStub().GetState(tradeKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to get trade: %v", err)
+	}
+	if tradeBytes == nil {
+		return false, fmt.Errorf("trade %s does not exist", tradeID)
+	}
+
+	var trade TradeRecord
+	if err := json.Unmarshal(tradeBytes, &trade); err != nil {
+		return false, fmt.Errorf("failed to unmarshal trade: %v", err)
+	}
+
+	actualHash, err := canonicalHash(trade)
+	if err != nil {
+		return false, err
+	}
+
+	return actualHash == expectedHash, nil
+}
+
+// canonicalHash computes the sha256, as a hex string, of v's canonical
+// JSON representation: its fields re-marshaled through a map so keys are
+// sorted, giving a stable hash regardless of struct field order. Used both
+// to verify on-chain trade integrity and to commit to off-chain trade
+// details.
+func canonicalHash(v interface{}) (string, error) {
+	valueBytes, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal value: %v", err)
+	}
+
+	var canonical map[string]interface{}
+	if err := json.Unmarshal(valueBytes, &canonical); err != nil {
+		return "", fmt.Errorf("failed to canonicalize value: %v", err)
+	}
+
+	sortedBytes, err := json.Marshal(canonical)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal canonical value: %v", err)
+	}
+
+	sum := sha256.Sum256(sortedBytes)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// GrantTradeReadAccess lets the identity that owns userID's trades delegate
+// read-only access to another identity, e.g. a compliance auditor. Only the
+// owner or an admin may grant access.
+func (s *AurumChaincode) GrantTradeReadAccess(ctx contractapi.TransactionContextInterface, userID string, delegateMSPID string, delegateCommonName string) error {
+	acl, err := loadACL(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if acl == nil {
+		return fmt.Errorf("no trades recorded for user %s", userID)
+	}
+
+	isAdminCaller, err := isAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	if !isAdminCaller {
+		owner, err := currentOwner(ctx)
+		if err != nil {
+			return err
+		}
+		if !acl.Owner.Equal(owner) {
+			return fmt.Errorf("identity is not authorized to grant access to user %s's trades", userID)
+		}
+	}
+
+	delegate := Owner{MSPID: delegateMSPID, CommonName: delegateCommonName}
+	for _, reader := range acl.DelegatedReaders {
+		if reader.Equal(delegate) {
+			return nil
+		}
+	}
+	acl.DelegatedReaders = append(acl.DelegatedReaders, delegate)
+
+	return putACL(ctx, acl)
+}
+
+// newClientIdentity resolves the calling client's identity from the stub.
+// It is a package-level var, rather than a direct cid.New call, so tests
+// can substitute a fake cid.ClientIdentity without fabricating a signed
+// proposal.
+var newClientIdentity = func(ctx contractapi.TransactionContextInterface) (cid.ClientIdentity, error) {
+	return cid.New(ctx.GetStub())
+}
+
+// currentOwner derives an Owner from the calling client's MSP identity.
+func currentOwner(ctx contractapi.TransactionContextInterface) (Owner, error) {
+	identity, err := newClientIdentity(ctx)
+	if err != nil {
+		return Owner{}, fmt.Errorf("failed to read client identity: %v", err)
+	}
+
+	mspID, err := identity.GetMSPID()
+	if err != nil {
+		return Owner{}, fmt.Errorf("failed to read client MSP ID: %v", err)
+	}
+
+	cert, err := identity.GetX509Certificate()
+	if err != nil {
+		return Owner{}, fmt.Errorf("failed to read client certificate: %v", err)
+	}
+
+	return Owner{MSPID: mspID, CommonName: cert.Subject.CommonName}, nil
+}
+
+// isAdmin reports whether the calling client carries the hf.Type=admin
+// attribute or belongs to the AurumMSP platform-operator org.
+func isAdmin(ctx contractapi.TransactionContextInterface) (bool, error) {
+	identity, err := newClientIdentity(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to read client identity: %v", err)
+	}
+
+	attrValue, ok, err := identity.GetAttributeValue("hf.Type")
+	if err != nil {
+		return false, fmt.Errorf("failed to read client attributes: %v", err)
+	}
+	if ok && attrValue == "admin" {
+		return true, nil
+	}
+
+	mspID, err := identity.GetMSPID()
+	if err != nil {
+		return false, fmt.Errorf("failed to read client MSP ID: %v", err)
+	}
+
+	return mspID == AdminMSPID, nil
+}
+
+// requireAdmin rejects the call unless the caller is an admin identity, for
+// queries whose selector can span multiple users' records.
+func requireAdmin(ctx contractapi.TransactionContextInterface) error {
+	isAdminCaller, err := isAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	if !isAdminCaller {
+		return fmt.Errorf("identity is not authorized to run this query")
+	}
+	return nil
+}
+
+// bindUserOwnership binds userID to owner the first time a trade is
+// recorded for it, and rejects any later RecordTrade call made under the
+// same userID by a different identity (unless the caller is an admin).
+func bindUserOwnership(ctx contractapi.TransactionContextInterface, userID string, owner Owner) error {
+	acl, err := loadACL(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if acl == nil {
+		return putACL(ctx, &AccessControlList{UserID: userID, Owner: owner})
+	}
+
+	if acl.Owner.Equal(owner) {
+		return nil
+	}
+
+	isAdminCaller, err := isAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	if isAdminCaller {
+		return nil
+	}
+
+	return fmt.Errorf("identity is not authorized to record trades for user %s", userID)
+}
+
+// authorizeUserAccess rejects the call unless the caller is an admin, owns
+// userID's trades, or has been delegated read access via an ACL record.
+func authorizeUserAccess(ctx contractapi.TransactionContextInterface, userID string) error {
+	isAdminCaller, err := isAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	if isAdminCaller {
+		return nil
+	}
+
+	owner, err := currentOwner(ctx)
+	if err != nil {
+		return err
+	}
+
+	acl, err := loadACL(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if acl == nil {
+		return fmt.Errorf("identity is not authorized to access user %s's trades", userID)
+	}
+
+	if acl.Owner.Equal(owner) {
+		return nil
+	}
+	for _, reader := range acl.DelegatedReaders {
+		if reader.Equal(owner) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("identity is not authorized to access user %s's trades", userID)
+}
+
+// authorizeUserWrite rejects the call unless the caller is an admin or owns
+// userID's trades. Unlike authorizeUserAccess, a delegated reader added via
+// GrantTradeReadAccess does NOT pass this check — delegation is read-only,
+// so it must never admit a caller to a mutating path like CancelTrade,
+// UpdateTradeStatus, or settlement creation/finalization.
+func authorizeUserWrite(ctx contractapi.TransactionContextInterface, userID string) error {
+	isAdminCaller, err := isAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	if isAdminCaller {
+		return nil
+	}
+
+	owner, err := currentOwner(ctx)
+	if err != nil {
+		return err
+	}
+
+	acl, err := loadACL(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if acl == nil || !acl.Owner.Equal(owner) {
+		return fmt.Errorf("identity is not authorized to modify user %s's trades", userID)
+	}
+
+	return nil
+}
+
+// loadACL reads the ACL:<userID> record, returning (nil, nil) if it does
+// not exist yet.
+func loadACL(ctx contractapi.TransactionContextInterface, userID string) (*AccessControlList, error) {
+	aclKey := fmt.Sprintf("ACL:%s", userID)
+	aclBytes, err := ctx.GetStub().GetState(aclKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ACL for user %s: %v", userID, err)
+	}
+	if aclBytes == nil {
+		return nil, nil
+	}
+
+	var acl AccessControlList
+	if err := json.Unmarshal(aclBytes, &acl); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal ACL for user %s: %v", userID, err)
+	}
+	return &acl, nil
+}
+
+// putACL persists an ACL:<userID> record.
+func putACL(ctx contractapi.TransactionContextInterface, acl *AccessControlList) error {
+	aclKey := fmt.Sprintf("ACL:%s", acl.UserID)
+	aclBytes, err := json.Marshal(acl)
+	if err != nil {
+		return fmt.Errorf("failed to marshal ACL for user %s: %v", acl.UserID, err)
+	}
+	if err := ctx.GetStub().PutState(aclKey, aclBytes); err != nil {
+		return fmt.Errorf("failed to put ACL for user %s: %v", acl.UserID, err)
+	}
+	return nil
+}
+
+// isTransitionAllowed reports whether newStatus is reachable from status
+// according to the given transition table.
+func isTransitionAllowed(transitions map[string][]string, status string, newStatus string) bool {
+	for _, allowed := range transitions[status] {
+		if allowed == newStatus {
+			return true
+		}
+	}
+	return false
+}
+
+// txTimestamp returns the transaction's declared timestamp formatted as
+// RFC3339. It falls back to the current wall clock if the stub cannot
+// produce a tx timestamp, which should only happen outside a peer context.
+func txTimestamp(ctx contractapi.TransactionContextInterface) string {
+	ts, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return time.Now().UTC().Format(time.RFC3339)
+	}
+	return time.Unix(ts.Seconds, int64(ts.Nanos)).UTC().Format(time.RFC3339)
+}
+
+// emitTradeEvent marshals and publishes a TradeEvent under the given event
+// name via the transaction's event stream.
+func emitTradeEvent(ctx contractapi.TransactionContextInterface, name string, evt TradeEvent) error {
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %v", name, err)
+	}
+	return ctx.GetStub().SetEvent(name, payload)
+}
+
+// emitSettlementEvent marshals and publishes a SettlementEvent under the
+// given event name via the transaction's event stream.
+func emitSettlementEvent(ctx contractapi.TransactionContextInterface, name string, evt SettlementEvent) error {
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %v", name, err)
+	}
+	return ctx.GetStub().SetEvent(name, payload)
+}
+
 func main() {
 	aurumChaincode, err := contractapi.NewChaincode(&AurumChaincode{})
 	if err != nil {
@@ -138,4 +1232,3 @@ func main() {
 		log.Panicf("Error starting aurum chaincode: %v", err)
 	}
 }
-
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// mockStub implements shim.ChaincodeStubInterface, overriding only the
+// methods exercised by the ACL and ownership helpers under test. Any other
+// method call panics on the embedded nil interface, which is fine as long
+// as a test doesn't reach it.
+type mockStub struct {
+	shim.ChaincodeStubInterface
+	state     map[string][]byte
+	lastQuery string
+}
+
+func newMockStub() *mockStub {
+	return &mockStub{state: make(map[string][]byte)}
+}
+
+func (m *mockStub) GetState(key string) ([]byte, error) {
+	return m.state[key], nil
+}
+
+func (m *mockStub) PutState(key string, value []byte) error {
+	m.state[key] = value
+	return nil
+}
+
+func (m *mockStub) SetEvent(name string, payload []byte) error {
+	return nil
+}
+
+// GetTxTimestamp returns a fixed timestamp, used for the event envelopes
+// emitted by status transitions.
+func (m *mockStub) GetTxTimestamp() (*timestamppb.Timestamp, error) {
+	return &timestamppb.Timestamp{Seconds: 1700000000}, nil
+}
+
+// GetQueryResultWithPagination records the selector it was called with and
+// returns an empty result set, so tests can assert on the selector JSON
+// without a real CouchDB.
+func (m *mockStub) GetQueryResultWithPagination(query string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	m.lastQuery = query
+	return &emptyIterator{}, &peer.QueryResponseMetadata{Bookmark: bookmark}, nil
+}
+
+// emptyIterator is a shim.StateQueryIteratorInterface with no results.
+type emptyIterator struct{}
+
+func (emptyIterator) HasNext() bool { return false }
+func (emptyIterator) Close() error  { return nil }
+func (emptyIterator) Next() (*queryresult.KV, error) {
+	return nil, fmt.Errorf("no results")
+}
+
+// mockTxContext implements contractapi.TransactionContextInterface,
+// returning a fixed mockStub from GetStub.
+type mockTxContext struct {
+	contractapi.TransactionContextInterface
+	stub *mockStub
+}
+
+func (m *mockTxContext) GetStub() shim.ChaincodeStubInterface {
+	return m.stub
+}
+
+// fakeClientIdentity implements cid.ClientIdentity for tests, so ownership
+// and authorization logic can be exercised without a signed proposal.
+type fakeClientIdentity struct {
+	mspID      string
+	commonName string
+	attrs      map[string]string
+}
+
+func (f fakeClientIdentity) GetID() (string, error) { return f.commonName, nil }
+
+func (f fakeClientIdentity) GetMSPID() (string, error) { return f.mspID, nil }
+
+func (f fakeClientIdentity) GetAttributeValue(attrName string) (string, bool, error) {
+	value, ok := f.attrs[attrName]
+	return value, ok, nil
+}
+
+func (f fakeClientIdentity) AssertAttributeValue(attrName, attrValue string) error {
+	if value, ok := f.attrs[attrName]; ok && value == attrValue {
+		return nil
+	}
+	return fmt.Errorf("attribute %s does not have value %s", attrName, attrValue)
+}
+
+func (f fakeClientIdentity) GetX509Certificate() (*x509.Certificate, error) {
+	return &x509.Certificate{Subject: pkix.Name{CommonName: f.commonName}}, nil
+}
+
+// asIdentity overrides newClientIdentity for the duration of a test.
+func asIdentity(t *testing.T, identity cid.ClientIdentity) {
+	t.Helper()
+	previous := newClientIdentity
+	newClientIdentity = func(contractapi.TransactionContextInterface) (cid.ClientIdentity, error) {
+		return identity, nil
+	}
+	t.Cleanup(func() { newClientIdentity = previous })
+}
+
+func trader(commonName string) fakeClientIdentity {
+	return fakeClientIdentity{mspID: "TraderOrgMSP", commonName: commonName}
+}
+
+func admin() fakeClientIdentity {
+	return fakeClientIdentity{mspID: AdminMSPID, commonName: "aurum-admin"}
+}
+
+func TestBindUserOwnershipBindsOnFirstCallAndRejectsOtherOwners(t *testing.T) {
+	ctx := &mockTxContext{stub: newMockStub()}
+
+	asIdentity(t, trader("alice"))
+	if err := bindUserOwnership(ctx, "user-1", Owner{MSPID: "TraderOrgMSP", CommonName: "alice"}); err != nil {
+		t.Fatalf("first bind: unexpected error: %v", err)
+	}
+	if err := bindUserOwnership(ctx, "user-1", Owner{MSPID: "TraderOrgMSP", CommonName: "alice"}); err != nil {
+		t.Fatalf("rebind by same owner: unexpected error: %v", err)
+	}
+
+	asIdentity(t, trader("mallory"))
+	if err := bindUserOwnership(ctx, "user-1", Owner{MSPID: "TraderOrgMSP", CommonName: "mallory"}); err == nil {
+		t.Fatal("expected bind by a different owner to be rejected")
+	}
+}
+
+func TestAuthorizeUserAccessOwnerDelegateAndAdmin(t *testing.T) {
+	ctx := &mockTxContext{stub: newMockStub()}
+
+	asIdentity(t, trader("alice"))
+	if err := bindUserOwnership(ctx, "user-1", Owner{MSPID: "TraderOrgMSP", CommonName: "alice"}); err != nil {
+		t.Fatalf("bind: unexpected error: %v", err)
+	}
+	if err := authorizeUserAccess(ctx, "user-1"); err != nil {
+		t.Fatalf("owner should be authorized: %v", err)
+	}
+
+	asIdentity(t, trader("mallory"))
+	if err := authorizeUserAccess(ctx, "user-1"); err == nil {
+		t.Fatal("expected unrelated identity to be denied")
+	}
+
+	asIdentity(t, admin())
+	if err := authorizeUserAccess(ctx, "user-1"); err != nil {
+		t.Fatalf("admin should be authorized: %v", err)
+	}
+	if err := authorizeUserAccess(ctx, "no-such-user"); err != nil {
+		t.Fatalf("admin should be authorized even with no ACL: %v", err)
+	}
+}
+
+func TestGrantTradeReadAccessDelegatesReadOnly(t *testing.T) {
+	ctx := &mockTxContext{stub: newMockStub()}
+
+	asIdentity(t, trader("alice"))
+	if err := bindUserOwnership(ctx, "user-1", Owner{MSPID: "TraderOrgMSP", CommonName: "alice"}); err != nil {
+		t.Fatalf("bind: unexpected error: %v", err)
+	}
+
+	asIdentity(t, trader("mallory"))
+	if err := (&AurumChaincode{}).GrantTradeReadAccess(ctx, "user-1", "ComplianceOrgMSP", "carol"); err == nil {
+		t.Fatal("expected non-owner, non-admin grant to be rejected")
+	}
+
+	asIdentity(t, trader("alice"))
+	if err := (&AurumChaincode{}).GrantTradeReadAccess(ctx, "user-1", "ComplianceOrgMSP", "carol"); err != nil {
+		t.Fatalf("owner grant: unexpected error: %v", err)
+	}
+
+	asIdentity(t, fakeClientIdentity{mspID: "ComplianceOrgMSP", commonName: "carol"})
+	if err := authorizeUserAccess(ctx, "user-1"); err != nil {
+		t.Fatalf("delegated reader should be authorized: %v", err)
+	}
+}
+
+func TestDelegatedReaderCannotMutateTrades(t *testing.T) {
+	ctx := &mockTxContext{stub: newMockStub()}
+	alice := Owner{MSPID: "TraderOrgMSP", CommonName: "alice"}
+
+	asIdentity(t, trader("alice"))
+	if err := bindUserOwnership(ctx, "user-1", alice); err != nil {
+		t.Fatalf("bind: unexpected error: %v", err)
+	}
+	if err := (&AurumChaincode{}).GrantTradeReadAccess(ctx, "user-1", "ComplianceOrgMSP", "carol"); err != nil {
+		t.Fatalf("owner grant: unexpected error: %v", err)
+	}
+	putTrade(t, ctx.stub, TradeRecord{
+		TradeID: "T1",
+		UserID:  "user-1",
+		Symbol:  "AAPL",
+		Side:    TradeSideBuy,
+		Status:  TradeStatusOpen,
+		Owner:   alice,
+	})
+
+	asIdentity(t, fakeClientIdentity{mspID: "ComplianceOrgMSP", commonName: "carol"})
+	if err := authorizeUserAccess(ctx, "user-1"); err != nil {
+		t.Fatalf("delegated reader should still be authorized to read: %v", err)
+	}
+	if err := (&AurumChaincode{}).CancelTrade(ctx, "T1"); err == nil {
+		t.Fatal("expected a read-only delegate to be rejected from CancelTrade")
+	}
+	if err := (&AurumChaincode{}).UpdateTradeStatus(ctx, "T1", TradeStatusCancelled); err == nil {
+		t.Fatal("expected a read-only delegate to be rejected from UpdateTradeStatus")
+	}
+
+	asIdentity(t, trader("alice"))
+	if err := (&AurumChaincode{}).CancelTrade(ctx, "T1"); err != nil {
+		t.Fatalf("owner should still be able to cancel: %v", err)
+	}
+}
+
+// putTrade writes a TradeRecord directly into stub state, bypassing
+// RecordTrade, so tests can set up a trade without wiring up the private
+// data collection RecordTrade also writes to.
+func putTrade(t *testing.T, stub *mockStub, trade TradeRecord) {
+	t.Helper()
+	tradeBytes, err := json.Marshal(trade)
+	if err != nil {
+		t.Fatalf("marshal trade: %v", err)
+	}
+	if err := stub.PutState(fmt.Sprintf("TRADE:%s", trade.TradeID), tradeBytes); err != nil {
+		t.Fatalf("put trade: %v", err)
+	}
+}
+
+func TestQueryTradesBySymbolProducesExpectedSelector(t *testing.T) {
+	stub := newMockStub()
+	ctx := &mockTxContext{stub: stub}
+	asIdentity(t, admin())
+
+	if _, err := (&AurumChaincode{}).QueryTradesBySymbol(ctx, "AAPL", 10, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `{"selector":{"symbol":"AAPL"}}`
+	if stub.lastQuery != want {
+		t.Fatalf("selector = %q, want %q", stub.lastQuery, want)
+	}
+}
+
+func TestQueryTradesByStrategyProducesExpectedSelector(t *testing.T) {
+	stub := newMockStub()
+	ctx := &mockTxContext{stub: stub}
+	asIdentity(t, admin())
+
+	if _, err := (&AurumChaincode{}).QueryTradesByStrategy(ctx, "mean-reversion", 10, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `{"selector":{"strategy":"mean-reversion"}}`
+	if stub.lastQuery != want {
+		t.Fatalf("selector = %q, want %q", stub.lastQuery, want)
+	}
+}
+
+func TestQueryOpenTradesByUserProducesExpectedSelectorAndIsScoped(t *testing.T) {
+	stub := newMockStub()
+	ctx := &mockTxContext{stub: stub}
+
+	asIdentity(t, trader("alice"))
+	if err := bindUserOwnership(ctx, "user-1", Owner{MSPID: "TraderOrgMSP", CommonName: "alice"}); err != nil {
+		t.Fatalf("bind: unexpected error: %v", err)
+	}
+
+	if _, err := (&AurumChaincode{}).QueryOpenTradesByUser(ctx, "user-1", 10, ""); err != nil {
+		t.Fatalf("owner query: unexpected error: %v", err)
+	}
+	want := `{"selector":{"status":"OPEN","user_id":"user-1"}}`
+	if stub.lastQuery != want {
+		t.Fatalf("selector = %q, want %q", stub.lastQuery, want)
+	}
+
+	asIdentity(t, trader("mallory"))
+	if _, err := (&AurumChaincode{}).QueryOpenTradesByUser(ctx, "user-1", 10, ""); err == nil {
+		t.Fatal("expected unrelated identity to be denied")
+	}
+}
+
+func TestQueryTradesBySymbolRejectsNonAdmin(t *testing.T) {
+	ctx := &mockTxContext{stub: newMockStub()}
+	asIdentity(t, trader("alice"))
+
+	if _, err := (&AurumChaincode{}).QueryTradesBySymbol(ctx, "AAPL", 10, ""); err == nil {
+		t.Fatal("expected non-admin caller to be rejected")
+	}
+}